@@ -41,13 +41,13 @@ func ʕ[T any](q bool, a T, b T) T {
 func (c Color) Blend(over Color) Color { // Color.blend
 	var res Color
 	var sa = 1.0 - over[a]
-	res[a] = a*sa + over[a]
+	res[a] = c[a]*sa + over[a]
 	if res[a] == 0 {
 		return Color{}
 	} else {
-		res[r] = (r*a*sa + over[r]*over[a]) / res[a]
-		res[g] = (g*a*sa + over[g]*over[a]) / res[a]
-		res[b] = (b*a*sa + over[b]*over[a]) / res[a]
+		res[r] = (c[r]*c[a]*sa + over[r]*over[a]) / res[a]
+		res[g] = (c[g]*c[a]*sa + over[g]*over[a]) / res[a]
+		res[b] = (c[b]*c[a]*sa + over[b]*over[a]) / res[a]
 	}
 	return res
 }
@@ -153,22 +153,24 @@ func (c Color) Lightened(amount float64) Color { // Color.lightened
 // SRGB returns the color converted to the sRGB color space. This method assumes the original color is
 // in the linear color space. See also [Color.Linear] which performs the opposite operation.
 func (c Color) SRGB() Color { // Color.linear_to_srgb
+	var rf, gf, bf = float64(c[r]), float64(c[g]), float64(c[b])
 	return Color{
-		float32(ʕ(r < 0.0031308, 12.92*r, (1.0+0.055)*math.Pow(r, 1.0/2.4)-0.055)),
-		float32(ʕ(g < 0.0031308, 12.92*g, (1.0+0.055)*math.Pow(g, 1.0/2.4)-0.055)),
-		float32(ʕ(b < 0.0031308, 12.92*b, (1.0+0.055)*math.Pow(b, 1.0/2.4)-0.055)),
-		a,
+		float32(ʕ(rf < 0.0031308, 12.92*rf, (1.0+0.055)*math.Pow(rf, 1.0/2.4)-0.055)),
+		float32(ʕ(gf < 0.0031308, 12.92*gf, (1.0+0.055)*math.Pow(gf, 1.0/2.4)-0.055)),
+		float32(ʕ(bf < 0.0031308, 12.92*bf, (1.0+0.055)*math.Pow(bf, 1.0/2.4)-0.055)),
+		c[a],
 	}
 }
 
 // Linear returns the color converted to the linear color space. This method assumes the original color
 // already is in the sRGB color space. See also [Color.SRGB] which performs the opposite operation.
 func (c Color) Linear() Color { // Color.srgb_to_linear
+	var rf, gf, bf = float64(c[r]), float64(c[g]), float64(c[b])
 	return Color{
-		float32(ʕ(r <= 0.04045, r/12.92, math.Pow((r+0.055)/1.055, 2.4))),
-		float32(ʕ(g <= 0.04045, g/12.92, math.Pow((g+0.055)/1.055, 2.4))),
-		float32(ʕ(b <= 0.04045, b/12.92, math.Pow((b+0.055)/1.055, 2.4))),
-		a,
+		float32(ʕ(rf <= 0.04045, rf/12.92, math.Pow((rf+0.055)/1.055, 2.4))),
+		float32(ʕ(gf <= 0.04045, gf/12.92, math.Pow((gf+0.055)/1.055, 2.4))),
+		float32(ʕ(bf <= 0.04045, bf/12.92, math.Pow((bf+0.055)/1.055, 2.4))),
+		c[a],
 	}
 }
 
@@ -0,0 +1,372 @@
+package uc
+
+import "math"
+
+/*
+LinearSRGB represents a color in the linear sRGB color space (the same primaries as sRGB, but
+without the gamma-like transfer function). This is the space in which most physically meaningful
+operations, such as lighting or averaging, should be performed. See also [Color.LinearSRGB] and
+[LinearSRGB.Color].
+*/
+type LinearSRGB struct{ R, G, B float64 }
+
+// XYZ represents a color in the CIE 1931 XYZ color space, relative to the D65 white point. XYZ is
+// a device-independent space and is used here as the hub between [LinearSRGB], [Lab] and [Oklab].
+type XYZ struct{ X, Y, Z float64 }
+
+// Lab represents a color in the CIE L*a*b* color space, relative to the D65 white point. L ranges
+// from 0 (black) to 100 (white); a and b are unbounded opponent axes (green-red and blue-yellow).
+type Lab struct{ L, A, B float64 }
+
+// LCH represents a color in the cylindrical form of [Lab]: lightness, chroma (colorfulness) and
+// hue. H is a fraction of a full turn between 0.0 and 1.0, matching the convention used by [HSV].
+type LCH struct{ L, C, H float64 }
+
+// Oklab represents a color in the Oklab perceptual color space (Björn Ottosson, 2020). Like [Lab],
+// L is lightness and A/B are opponent axes, but Oklab's components are far more perceptually
+// uniform, which makes it a good space to mix or interpolate colors in. See [Color.Oklab].
+type Oklab struct{ L, A, B float64 }
+
+// Oklch represents a color in the cylindrical form of [Oklab]: lightness, chroma and hue. H is a
+// fraction of a full turn between 0.0 and 1.0, matching the convention used by [HSV]. Oklch is a
+// convenient space to build tonal palettes in, since hue can be held fixed while lightness varies.
+type Oklch struct{ L, C, H float64 }
+
+// HSL represents a color as hue, saturation and lightness, each typically between 0.0 and 1.0. HSL
+// is computed directly from a [Color]'s (gamma-encoded) components, mirroring how [HSV] operates.
+type HSL struct{ H, S, L float64 }
+
+// ColorSpace identifies a color space that [Color.LerpIn] and [Color.BlendIn] can operate in.
+type ColorSpace int
+
+const (
+	// SpaceSRGB interpolates the gamma-encoded components directly, matching [Color.Lerp].
+	SpaceSRGB ColorSpace = iota
+	// SpaceLinear interpolates in linear light, avoiding the darkening midpoints of SpaceSRGB.
+	SpaceLinear
+	// SpaceOklab interpolates in the Oklab space, giving perceptually uniform gradients.
+	SpaceOklab
+	// SpaceOklch interpolates lightness, chroma and hue independently, taking the shortest hue path.
+	SpaceOklch
+)
+
+// LinearSRGB returns c converted to the linear sRGB color space, undoing the sRGB transfer function.
+func (c Color) LinearSRGB() LinearSRGB {
+	var l = c.Linear()
+	return LinearSRGB{float64(l[r]), float64(l[g]), float64(l[b])}
+}
+
+// Color returns l converted to a [Color] in the (gamma-encoded) sRGB color space, with alpha 1.
+func (l LinearSRGB) Color() Color {
+	return NewColor(l.R, l.G, l.B, 1).SRGB()
+}
+
+var (
+	// srgbToXYZ is the standard D65 linear-sRGB -> XYZ matrix.
+	srgbToXYZ = [3][3]float64{
+		{0.4124564, 0.3575761, 0.1804375},
+		{0.2126729, 0.7151522, 0.0721750},
+		{0.0193339, 0.1191920, 0.9503041},
+	}
+	// xyzToSRGB is the inverse of srgbToXYZ.
+	xyzToSRGB = [3][3]float64{
+		{3.2404542, -1.5371385, -0.4985314},
+		{-0.9692660, 1.8760108, 0.0415560},
+		{0.0556434, -0.2040259, 1.0572252},
+	}
+)
+
+func matmul3(m [3][3]float64, x, y, z float64) (a, b, c float64) {
+	return m[0][0]*x + m[0][1]*y + m[0][2]*z,
+		m[1][0]*x + m[1][1]*y + m[1][2]*z,
+		m[2][0]*x + m[2][1]*y + m[2][2]*z
+}
+
+// XYZ returns l converted to the CIE 1931 XYZ color space (D65 white point).
+func (l LinearSRGB) XYZ() XYZ {
+	x, y, z := matmul3(srgbToXYZ, l.R, l.G, l.B)
+	return XYZ{x, y, z}
+}
+
+// LinearSRGB returns x converted to the linear sRGB color space (D65 white point).
+func (x XYZ) LinearSRGB() LinearSRGB {
+	r, g, b := matmul3(xyzToSRGB, x.X, x.Y, x.Z)
+	return LinearSRGB{r, g, b}
+}
+
+// XYZ returns c converted to the CIE 1931 XYZ color space via [Color.LinearSRGB].
+func (c Color) XYZ() XYZ { return c.LinearSRGB().XYZ() }
+
+// Color returns x converted to a [Color] in the sRGB color space, with alpha 1.
+func (x XYZ) Color() Color { return x.LinearSRGB().Color() }
+
+// D65 reference white, used by [XYZ.Lab] and [Lab.XYZ].
+const (
+	d65X = 0.95047
+	d65Y = 1.0
+	d65Z = 1.08883
+)
+
+func labF(t float64) float64 {
+	const (
+		eps   = 216.0 / 24389.0
+		kappa = 24389.0 / 27.0
+	)
+	if t > eps {
+		return math.Cbrt(t)
+	}
+	return (kappa*t + 16.0) / 116.0
+}
+
+func labFInv(t float64) float64 {
+	const (
+		eps   = 216.0 / 24389.0
+		kappa = 24389.0 / 27.0
+	)
+	if t3 := t * t * t; t3 > eps {
+		return t3
+	}
+	return (116.0*t - 16.0) / kappa
+}
+
+// Lab returns x converted to the CIE L*a*b* color space (D65 white point).
+func (x XYZ) Lab() Lab {
+	var fx, fy, fz = labF(x.X / d65X), labF(x.Y / d65Y), labF(x.Z / d65Z)
+	return Lab{
+		L: 116*fy - 16,
+		A: 500 * (fx - fy),
+		B: 200 * (fy - fz),
+	}
+}
+
+// XYZ returns l converted to the CIE 1931 XYZ color space (D65 white point).
+func (l Lab) XYZ() XYZ {
+	var fy = (l.L + 16) / 116
+	var fx = fy + l.A/500
+	var fz = fy - l.B/200
+	return XYZ{
+		X: labFInv(fx) * d65X,
+		Y: labFInv(fy) * d65Y,
+		Z: labFInv(fz) * d65Z,
+	}
+}
+
+// LCH returns l converted to its cylindrical form.
+func (l Lab) LCH() LCH {
+	var h = math.Atan2(l.B, l.A) / (2 * math.Pi)
+	if h < 0 {
+		h += 1
+	}
+	return LCH{L: l.L, C: math.Hypot(l.A, l.B), H: h}
+}
+
+// Lab returns l converted back to its rectangular form.
+func (l LCH) Lab() Lab {
+	var theta = l.H * 2 * math.Pi
+	return Lab{L: l.L, A: l.C * math.Cos(theta), B: l.C * math.Sin(theta)}
+}
+
+var (
+	// xyzToLMS and lmsToOklab implement Björn Ottosson's Oklab pipeline.
+	xyzToLMS = [3][3]float64{
+		{0.8189330101, 0.3618667424, -0.1288597137},
+		{0.0329845436, 0.9293118715, 0.0361456387},
+		{0.0482003018, 0.2643662691, 0.6338517070},
+	}
+	lmsToOklab = [3][3]float64{
+		{0.2104542553, 0.7936177850, -0.0040720468},
+		{1.9779984951, -2.4285922050, 0.4505937099},
+		{0.0259040371, 0.7827717662, -0.8086757660},
+	}
+	oklabToLMS = [3][3]float64{
+		{1, 0.3963377774, 0.2158037573},
+		{1, -0.1055613458, -0.0638541728},
+		{1, -0.0894841775, -1.2914855480},
+	}
+	lmsToXYZ = [3][3]float64{
+		{1.2270138511, -0.5577999807, 0.2812561490},
+		{-0.0405801784, 1.1122568696, -0.0716766787},
+		{-0.0763812845, -0.4214819784, 1.5861632204},
+	}
+)
+
+// Oklab returns x converted to the Oklab color space.
+func (x XYZ) Oklab() Oklab {
+	l, m, s := matmul3(xyzToLMS, x.X, x.Y, x.Z)
+	l, m, s = math.Cbrt(l), math.Cbrt(m), math.Cbrt(s)
+	L, A, B := matmul3(lmsToOklab, l, m, s)
+	return Oklab{L, A, B}
+}
+
+// XYZ returns o converted to the CIE 1931 XYZ color space.
+func (o Oklab) XYZ() XYZ {
+	l, m, s := matmul3(oklabToLMS, o.L, o.A, o.B)
+	l, m, s = l*l*l, m*m*m, s*s*s
+	x, y, z := matmul3(lmsToXYZ, l, m, s)
+	return XYZ{x, y, z}
+}
+
+// Oklab returns c converted to the Oklab color space via [Color.XYZ].
+func (c Color) Oklab() Oklab { return c.XYZ().Oklab() }
+
+// Color returns o converted to a [Color] in the sRGB color space, with alpha 1.
+func (o Oklab) Color() Color { return o.XYZ().Color() }
+
+// Oklch returns o converted to its cylindrical form.
+func (o Oklab) Oklch() Oklch {
+	var h = math.Atan2(o.B, o.A) / (2 * math.Pi)
+	if h < 0 {
+		h += 1
+	}
+	return Oklch{L: o.L, C: math.Hypot(o.A, o.B), H: h}
+}
+
+// Oklab returns o converted back to its rectangular form.
+func (o Oklch) Oklab() Oklab {
+	var theta = o.H * 2 * math.Pi
+	return Oklab{L: o.L, A: o.C * math.Cos(theta), B: o.C * math.Sin(theta)}
+}
+
+// Oklch returns c converted to the Oklch color space via [Color.Oklab].
+func (c Color) Oklch() Oklch { return c.Oklab().Oklch() }
+
+// Color returns o converted to a [Color] in the sRGB color space, with alpha 1.
+func (o Oklch) Color() Color { return o.Oklab().Color() }
+
+// WithHue returns a copy of o with its hue replaced by h (a fraction of a full turn).
+func (o Oklch) WithHue(h float64) Oklch { o.H = h; return o }
+
+// Mix returns the color that is weight of the way from o to other, interpolating L, C and H
+// independently. weight should be between 0.0 and 1.0 (inclusive).
+func (o Oklch) Mix(other Oklch, weight float64) Oklch {
+	return Oklch{
+		L: lerpf(o.L, other.L, weight),
+		C: lerpf(o.C, other.C, weight),
+		H: lerpHue(o.H, other.H, weight),
+	}
+}
+
+// lerpHue interpolates a hue fraction (0.0-1.0) taking the shorter path around the wheel.
+func lerpHue(from, to, weight float64) float64 {
+	var delta = math.Mod(to-from+1.5, 1) - 0.5
+	var h = from + delta*weight
+	return math.Mod(math.Mod(h, 1)+1, 1)
+}
+
+// Mix returns the color that is weight of the way from o to other, interpolating L, A and B.
+// Mixing in Oklab produces more perceptually even gradients than mixing sRGB or linear values.
+func (o Oklab) Mix(other Oklab, weight float64) Oklab {
+	return Oklab{
+		L: lerpf(o.L, other.L, weight),
+		A: lerpf(o.A, other.A, weight),
+		B: lerpf(o.B, other.B, weight),
+	}
+}
+
+// HSL returns c converted to hue, saturation and lightness, computed directly from c's components
+// (mirroring how [HSV] operates, rather than converting via linear light).
+func (c Color) HSL() HSL {
+	var (
+		max = math.Max(float64(c[r]), math.Max(float64(c[g]), float64(c[b])))
+		min = math.Min(float64(c[r]), math.Min(float64(c[g]), float64(c[b])))
+		l   = (max + min) / 2
+	)
+	if max == min {
+		return HSL{H: 0, S: 0, L: l}
+	}
+	var d = max - min
+	var s float64
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+	var h float64
+	switch max {
+	case float64(c[r]):
+		h = (float64(c[g]) - float64(c[b])) / d
+		if c[g] < c[b] {
+			h += 6
+		}
+	case float64(c[g]):
+		h = (float64(c[b])-float64(c[r]))/d + 2
+	default:
+		h = (float64(c[r])-float64(c[g]))/d + 4
+	}
+	return HSL{H: h / 6, S: s, L: l}
+}
+
+// Color returns h converted to a [Color] with alpha 1.
+func (h HSL) Color() Color {
+	if h.S == 0 {
+		return NewColor(h.L, h.L, h.L, 1)
+	}
+	var q float64
+	if h.L < 0.5 {
+		q = h.L * (1 + h.S)
+	} else {
+		q = h.L + h.S - h.L*h.S
+	}
+	var p = 2*h.L - q
+	return NewColor(hueToRGB(p, q, h.H+1.0/3.0), hueToRGB(p, q, h.H), hueToRGB(p, q, h.H-1.0/3.0), 1)
+}
+
+func hueToRGB(p, q, t float64) float64 {
+	t = math.Mod(math.Mod(t, 1)+1, 1)
+	switch {
+	case t < 1.0/6.0:
+		return p + (q-p)*6*t
+	case t < 1.0/2.0:
+		return q
+	case t < 2.0/3.0:
+		return p + (q-p)*(2.0/3.0-t)*6
+	default:
+		return p
+	}
+}
+
+// LerpIn returns the interpolation between c and to, performed in the given [ColorSpace] instead
+// of gamma-encoded sRGB. This avoids the muddy, over-darkened midpoints that [Color.Lerp] can
+// produce, especially between hues that are far apart. Alpha is always interpolated linearly.
+func (c Color) LerpIn(to Color, weight float64, space ColorSpace) Color {
+	switch space {
+	case SpaceLinear:
+		var from, dst = c.LinearSRGB(), to.LinearSRGB()
+		var mixed = LinearSRGB{
+			lerpf(from.R, dst.R, weight),
+			lerpf(from.G, dst.G, weight),
+			lerpf(from.B, dst.B, weight),
+		}
+		var res = mixed.Color()
+		res[a] = float32(lerpf(float64(c[a]), float64(to[a]), weight))
+		return res
+	case SpaceOklab:
+		var res = c.Oklab().Mix(to.Oklab(), weight).Color()
+		res[a] = float32(lerpf(float64(c[a]), float64(to[a]), weight))
+		return res
+	case SpaceOklch:
+		var res = c.Oklch().Mix(to.Oklch(), weight).Color()
+		res[a] = float32(lerpf(float64(c[a]), float64(to[a]), weight))
+		return res
+	default:
+		return c.Lerp(to, weight)
+	}
+}
+
+// BlendIn returns the result of overlaying over on top of c (see [Color.Blend]), but with the RGB
+// mixing step performed in the given [ColorSpace] rather than gamma-encoded sRGB.
+func (c Color) BlendIn(over Color, space ColorSpace) Color {
+	if space == SpaceSRGB {
+		return c.Blend(over)
+	}
+	var res Color
+	var sa = 1.0 - over[a]
+	res[a] = c[a]*sa + over[a]
+	if res[a] == 0 {
+		return Color{}
+	}
+	var weight = float64(over[a]) / float64(res[a])
+	var mixed = c.LerpIn(over, weight, space)
+	res[r], res[g], res[b] = mixed[r], mixed[g], mixed[b]
+	return res
+}
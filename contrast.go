@@ -0,0 +1,92 @@
+package uc
+
+import "math"
+
+// ContrastRatio returns the WCAG 2.x contrast ratio between c and other, a value between 1.0 (no
+// contrast) and 21.0 (black on white). It is computed as (L1+0.05)/(L2+0.05), where L1 is the
+// larger of the two colors' relative luminance and L2 the smaller, using [Color.Luminance] on the
+// linearized (see [Color.Linear]) colors as required by the WCAG formula.
+func (c Color) ContrastRatio(other Color) float64 {
+	var l1 = c.Linear().Luminance()
+	var l2 = other.Linear().Luminance()
+	if l1 < l2 {
+		l1, l2 = l2, l1
+	}
+	return (l1 + 0.05) / (l2 + 0.05)
+}
+
+// APCA (0.98G) constants, as published by the APCA-W3 reference implementation.
+const (
+	apcaNormBG    = 0.56
+	apcaNormTXT   = 0.57
+	apcaRevBG     = 0.65
+	apcaRevTXT    = 0.62
+	apcaBlkThresh = 0.022
+	apcaBlkClamp  = 1.414
+	apcaScale     = 1.14
+	apcaLoClip    = 0.1
+	apcaLoOffset  = 0.027
+	apcaDeltaYMin = 0.0005
+)
+
+// APCA returns the (simplified) APCA/SAPC perceptual contrast between text (this color, used as
+// foreground) and background, the algorithm proposed for WCAG 3. Unlike [Color.ContrastRatio],
+// the result is signed: positive when text is drawn on a lighter background, negative for a
+// darker one, and its magnitude (roughly 0-100+ "Lc" units) is not directly comparable to the
+// WCAG 2.x ratio. This implements the published constants but, being a compact library, does not
+// reproduce every edge-case clamp of the reference implementation.
+func (text Color) APCA(background Color) float64 {
+	var clampY = func(y float64) float64 {
+		if y > apcaBlkThresh {
+			return y
+		}
+		return y + math.Pow(apcaBlkThresh-y, apcaBlkClamp)
+	}
+	var txtY = clampY(text.Linear().Luminance())
+	var bgY = clampY(background.Linear().Luminance())
+	if math.Abs(bgY-txtY) < apcaDeltaYMin {
+		return 0
+	}
+	if bgY > txtY {
+		var sapc = (math.Pow(bgY, apcaNormBG) - math.Pow(txtY, apcaNormTXT)) * apcaScale
+		if sapc < apcaLoClip {
+			return 0
+		}
+		return (sapc - apcaLoOffset) * 100
+	}
+	var sapc = (math.Pow(bgY, apcaRevBG) - math.Pow(txtY, apcaRevTXT)) * apcaScale
+	if sapc > -apcaLoClip {
+		return 0
+	}
+	return (sapc + apcaLoOffset) * 100
+}
+
+// PickReadableForeground returns whichever of candidates has the highest [Color.ContrastRatio]
+// against c (the background). If candidates is empty, it chooses between black and white.
+func (c Color) PickReadableForeground(candidates ...Color) Color {
+	if len(candidates) == 0 {
+		candidates = []Color{NewColor(0, 0, 0, 1), NewColor(1, 1, 1, 1)}
+	}
+	var best = candidates[0]
+	var bestRatio = c.ContrastRatio(best)
+	for _, candidate := range candidates[1:] {
+		if ratio := c.ContrastRatio(candidate); ratio > bestRatio {
+			bestRatio, best = ratio, candidate
+		}
+	}
+	return best
+}
+
+// TonalPalette generates a Material Design-style tonal palette from seed: 13 colors sharing
+// seed's Oklch hue and chroma, sampled at the fixed lightness stops 0, 10, 20, ..., 90, 95, 99 and
+// 100 (index 0 is always black, index 12 always white). Each tone is gamut-mapped into sRGB (see
+// [Color.GamutMap]), since seed's chroma may not be reproducible at every lightness.
+func TonalPalette(seed Color) [13]Color {
+	var stops = [13]float64{0, 10, 20, 30, 40, 50, 60, 70, 80, 90, 95, 99, 100}
+	var base = seed.Oklch()
+	var palette [13]Color
+	for i, stop := range stops {
+		palette[i] = Oklch{L: stop / 100, C: base.C, H: base.H}.Color().GamutMap(GamutSRGB)
+	}
+	return palette
+}
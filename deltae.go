@@ -0,0 +1,222 @@
+package uc
+
+import "math"
+
+// DeltaEMethod selects the color-difference formula used by [Color.DeltaE].
+type DeltaEMethod int
+
+const (
+	// DeltaE76 is the plain Euclidean distance between two colors in CIE L*a*b*. It is the
+	// simplest metric but does not account for the eye's uneven sensitivity across hue and chroma.
+	DeltaE76 DeltaEMethod = iota
+	// DeltaE94 improves on DeltaE76 by weighting the lightness, chroma and hue differences
+	// separately (graphic-arts application weights: kL=1, K1=0.045, K2=0.015).
+	DeltaE94
+	// DeltaE2000 (CIEDE2000) is the most perceptually accurate of the CIE metrics, correcting for
+	// hue-dependent chroma/hue interaction, but is also the most expensive to compute.
+	DeltaE2000
+	// DeltaEOK is the Euclidean distance between two colors in [Oklab]. Because Oklab is already
+	// close to perceptually uniform, this is a good, cheap default for new code; note its scale is
+	// not comparable to the CIE metrics above.
+	DeltaEOK
+)
+
+// DeltaE returns the perceptual color difference between c and other, computed with method. A
+// larger value means the colors are more different; roughly, a DeltaE2000 value below 1.0 is
+// imperceptible to the human eye, and below 2.3 is a "just noticeable difference".
+func (c Color) DeltaE(other Color, method DeltaEMethod) float64 {
+	switch method {
+	case DeltaE94:
+		return deltaE94(c.XYZ().Lab(), other.XYZ().Lab())
+	case DeltaE2000:
+		return deltaE2000(c.XYZ().Lab(), other.XYZ().Lab())
+	case DeltaEOK:
+		var o1, o2 = c.Oklab(), other.Oklab()
+		return math.Sqrt(sq(o1.L-o2.L) + sq(o1.A-o2.A) + sq(o1.B-o2.B))
+	default: // DeltaE76
+		var l1, l2 = c.XYZ().Lab(), other.XYZ().Lab()
+		return math.Sqrt(sq(l1.L-l2.L) + sq(l1.A-l2.A) + sq(l1.B-l2.B))
+	}
+}
+
+func sq(v float64) float64 { return v * v }
+
+func deltaE94(l1, l2 Lab) float64 {
+	const kL, kC, kH, k1, k2 = 1.0, 1.0, 1.0, 0.045, 0.015
+	var (
+		c1 = math.Hypot(l1.A, l1.B)
+		c2 = math.Hypot(l2.A, l2.B)
+		dL = l1.L - l2.L
+		dC = c1 - c2
+		dA = l1.A - l2.A
+		dB = l1.B - l2.B
+	)
+	var dH = math.Sqrt(math.Max(0, dA*dA+dB*dB-dC*dC))
+	var (
+		sl = 1.0
+		sc = 1 + k1*c1
+		sh = 1 + k2*c1
+	)
+	return math.Sqrt(sq(dL/(kL*sl)) + sq(dC/(kC*sc)) + sq(dH/(kH*sh)))
+}
+
+// deltaE2000 implements CIEDE2000 (Sharma, Wu & Dalal, 2005) exactly as specified.
+func deltaE2000(l1, l2 Lab) float64 {
+	const kL, kC, kH = 1.0, 1.0, 1.0
+	var (
+		c1 = math.Hypot(l1.A, l1.B)
+		c2 = math.Hypot(l2.A, l2.B)
+	)
+	var cbar = (c1 + c2) / 2
+	var g = 0.5 * (1 - math.Sqrt(math.Pow(cbar, 7)/(math.Pow(cbar, 7)+math.Pow(25, 7))))
+	var (
+		a1p = (1 + g) * l1.A
+		a2p = (1 + g) * l2.A
+	)
+	var (
+		c1p = math.Hypot(a1p, l1.B)
+		c2p = math.Hypot(a2p, l2.B)
+	)
+	var (
+		h1p = atan2Deg(l1.B, a1p)
+		h2p = atan2Deg(l2.B, a2p)
+	)
+	var deltaLp = l2.L - l1.L
+	var deltaCp = c2p - c1p
+	var deltahp float64
+	if c1p*c2p != 0 {
+		var dh = h2p - h1p
+		switch {
+		case dh > 180:
+			dh -= 360
+		case dh < -180:
+			dh += 360
+		}
+		deltahp = dh
+	}
+	var deltaHp = 2 * math.Sqrt(c1p*c2p) * math.Sin(deg2rad(deltahp)/2)
+
+	var lbar = (l1.L + l2.L) / 2
+	var cbarp = (c1p + c2p) / 2
+	var hbarp float64
+	switch {
+	case c1p*c2p == 0:
+		hbarp = h1p + h2p
+	case math.Abs(h1p-h2p) <= 180:
+		hbarp = (h1p + h2p) / 2
+	case h1p+h2p < 360:
+		hbarp = (h1p + h2p + 360) / 2
+	default:
+		hbarp = (h1p + h2p - 360) / 2
+	}
+	var t = 1 - 0.17*math.Cos(deg2rad(hbarp-30)) + 0.24*math.Cos(deg2rad(2*hbarp)) +
+		0.32*math.Cos(deg2rad(3*hbarp+6)) - 0.20*math.Cos(deg2rad(4*hbarp-63))
+	var deltaTheta = 30 * math.Exp(-sq((hbarp-275)/25))
+	var rc = 2 * math.Sqrt(math.Pow(cbarp, 7)/(math.Pow(cbarp, 7)+math.Pow(25, 7)))
+	var sl = 1 + (0.015*sq(lbar-50))/math.Sqrt(20+sq(lbar-50))
+	var sc = 1 + 0.045*cbarp
+	var sh = 1 + 0.015*cbarp*t
+	var rt = -math.Sin(deg2rad(2*deltaTheta)) * rc
+
+	var (
+		termL = deltaLp / (kL * sl)
+		termC = deltaCp / (kC * sc)
+		termH = deltaHp / (kH * sh)
+	)
+	return math.Sqrt(termL*termL + termC*termC + termH*termH + rt*termC*termH)
+}
+
+func deg2rad(deg float64) float64 { return deg * math.Pi / 180 }
+
+// atan2Deg returns the angle of (x, y) in degrees, normalized to [0, 360).
+func atan2Deg(y, x float64) float64 {
+	if x == 0 && y == 0 {
+		return 0
+	}
+	var deg = math.Atan2(y, x) * 180 / math.Pi
+	if deg < 0 {
+		deg += 360
+	}
+	return deg
+}
+
+// Gamut identifies a target RGB color gamut for [Color.GamutMap].
+type Gamut int
+
+const (
+	// GamutSRGB is the sRGB gamut used by most displays and by [Color] itself.
+	GamutSRGB Gamut = iota
+	// GamutDisplayP3 is the wider gamut used by Apple displays and the CSS color(display-p3 ...) syntax.
+	GamutDisplayP3
+	// GamutRec2020 is the very wide gamut used by UHD/HDR video (Rec. 2100/Rec. 2020).
+	GamutRec2020
+)
+
+var (
+	// xyzToP3 is the inverse of the linear Display-P3 -> XYZ matrix used by [Parse].
+	xyzToP3 = [3][3]float64{
+		{2.4934969, -0.9313836, -0.4027108},
+		{-0.8294890, 1.7626641, 0.0236247},
+		{0.0358458, -0.0761724, 0.9568845},
+	}
+	// xyzToRec2020 is the linear XYZ -> Rec. 2020 matrix (D65).
+	xyzToRec2020 = [3][3]float64{
+		{1.7166512, -0.3556708, -0.2533663},
+		{-0.6666844, 1.6164812, 0.0157685},
+		{0.0176399, -0.0427706, 0.9421031},
+	}
+)
+
+const gamutEpsilon = 1e-4
+
+func inUnit(v float64) bool { return v >= -gamutEpsilon && v <= 1+gamutEpsilon }
+
+// inGamut reports whether c's components fall within target's RGB cube, checked in target's
+// linear light (which, since each channel's transfer function is a monotonic 0-1 to 0-1 mapping,
+// is equivalent to checking the gamma-encoded components).
+func (c Color) inGamut(target Gamut) bool {
+	switch target {
+	case GamutSRGB:
+		return inUnit(float64(c[r])) && inUnit(float64(c[g])) && inUnit(float64(c[b]))
+	default:
+		var x = c.XYZ()
+		var m = xyzToP3
+		if target == GamutRec2020 {
+			m = xyzToRec2020
+		}
+		lr, lg, lb := matmul3(m, x.X, x.Y, x.Z)
+		return inUnit(lr) && inUnit(lg) && inUnit(lb)
+	}
+}
+
+// GamutMap returns c reduced in chroma, if necessary, until it fits within target's RGB gamut,
+// preserving lightness and hue. It converts to [Oklch] and bisects on chroma against an in-gamut
+// predicate, which keeps the mapped color close to the original while guaranteeing the result can
+// be represented in target without clipping, as long as c's Oklch lightness lies within 0.0-1.0.
+// A color whose lightness is outside that range (such as an overbright HDR color) comes back with
+// zero chroma; for GamutSRGB it is then clamped into [0,1], while for the other gamuts it can
+// still lie outside target.
+func (c Color) GamutMap(target Gamut) Color {
+	if c.inGamut(target) {
+		return c
+	}
+	var oklch = c.Oklch()
+	var lo, hi = 0.0, oklch.C
+	for i := 0; i < 24; i++ {
+		var mid = (lo + hi) / 2
+		if (Oklch{L: oklch.L, C: mid, H: oklch.H}).Color().inGamut(target) {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	var mapped = Oklch{L: oklch.L, C: lo, H: oklch.H}.Color()
+	mapped[a] = c[a]
+	if target == GamutSRGB {
+		// The bisection already guarantees mapped is within target's gamut; sRGB is the only
+		// target whose gamut coincides with Color's own [0,1] encoding, so only it can be clamped
+		// this way. Display-P3/Rec.2020 colors legitimately have out-of-[0,1] sRGB components.
+		mapped = mapped.Clamp(Color{0, 0, 0, 0}, Color{1, 1, 1, 1})
+	}
+	return mapped
+}
@@ -0,0 +1,19 @@
+package uc
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDeltaE2000Sharma pins the CIEDE2000 implementation against the first pair from the
+// Sharma, Wu & Dalal (2005) reference dataset, so a future refactor of the recurrence can't
+// silently regress it.
+func TestDeltaE2000Sharma(t *testing.T) {
+	var c1 = Lab{L: 50.0000, A: 2.6772, B: -79.7751}.XYZ().Color()
+	var c2 = Lab{L: 50.0000, A: 0.0000, B: -82.7485}.XYZ().Color()
+	var got = c1.DeltaE(c2, DeltaE2000)
+	var want = 2.0425
+	if math.Abs(got-want) > 0.01 {
+		t.Errorf("DeltaE2000() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,174 @@
+package uc
+
+import "math"
+
+// EncodeRGBE9995 encodes c as an RGBE9995 format integer, the inverse of [RGBE9995]: the three
+// color components share a single 5-bit exponent, chosen so the largest component uses the full 9
+// bits of mantissa precision, with correct rounding and clamping of out-of-range/negative values.
+func EncodeRGBE9995(c Color) uint32 {
+	const (
+		mantissaBits = 9
+		expBias      = 15
+		maxBiasedExp = 31
+	)
+	const (
+		maxExp          = maxBiasedExp - expBias
+		mantissaValues  = 1 << mantissaBits
+		maxMantissa     = mantissaValues - 1
+		maxRepresenable = float64(maxMantissa) / mantissaValues * (1 << maxExp)
+	)
+	var clampChannel = func(v float32) float64 {
+		var x = float64(v)
+		switch {
+		case x <= 0:
+			return 0
+		case x >= maxRepresenable:
+			return maxRepresenable
+		default:
+			return x
+		}
+	}
+	var rc, gc, bc = clampChannel(c[r]), clampChannel(c[g]), clampChannel(c[b])
+	var maxc = math.Max(rc, math.Max(gc, bc))
+	if maxc <= 0 {
+		return 0
+	}
+	var expShared = int(math.Max(float64(-expBias-1), math.Floor(math.Log2(maxc)))) + 1 + expBias
+	var denom = math.Exp2(float64(expShared - expBias - mantissaBits))
+	if maxm := int(math.Floor(maxc/denom + 0.5)); maxm == maxMantissa+1 {
+		denom *= 2
+		expShared++
+	}
+	var round = func(v float64) uint32 {
+		var m = uint32(math.Floor(v/denom + 0.5))
+		if m > maxMantissa {
+			m = maxMantissa
+		}
+		return m
+	}
+	var rm, gm, bm = round(rc), round(gc), round(bc)
+	return uint32(expShared)<<27 | bm<<18 | gm<<9 | rm
+}
+
+// SMPTE ST 2084 (PQ) transfer function constants.
+const (
+	pqM1 = 0.1593017578125
+	pqM2 = 78.84375
+	pqC1 = 0.8359375
+	pqC2 = 18.8515625
+	pqC3 = 18.6875
+)
+
+// PQ returns c's r, g and b components encoded with the SMPTE ST 2084 (PQ, "perceptual
+// quantizer") transfer function used by Rec. 2100 HDR video. c is treated as scene-linear light
+// normalized so that 1.0 represents 10000 cd/m^2, the PQ reference peak. See [Color.FromPQ] for
+// the inverse.
+func (c Color) PQ() Color {
+	var encode = func(v float32) float32 {
+		var l = math.Max(0, float64(v))
+		var lm1 = math.Pow(l, pqM1)
+		return float32(math.Pow((pqC1+pqC2*lm1)/(1+pqC3*lm1), pqM2))
+	}
+	return Color{encode(c[r]), encode(c[g]), encode(c[b]), c[a]}
+}
+
+// FromPQ returns c, whose r, g and b components are PQ-encoded (see [Color.PQ]), decoded back to
+// scene-linear light normalized so that 1.0 represents 10000 cd/m^2.
+func (c Color) FromPQ() Color {
+	var decode = func(v float32) float32 {
+		var ep = math.Max(0, float64(v))
+		var epm = math.Pow(ep, 1/pqM2)
+		var num = math.Max(epm-pqC1, 0)
+		var den = pqC2 - pqC3*epm
+		return float32(math.Pow(num/den, 1/pqM1))
+	}
+	return Color{decode(c[r]), decode(c[g]), decode(c[b]), c[a]}
+}
+
+// Hybrid Log-Gamma (Rec. 2100 HLG) transfer function constants.
+const (
+	hlgA = 0.17883277
+	hlgB = 0.28466892
+	hlgC = 0.55991073
+)
+
+// HLG returns c's r, g and b components encoded with the Hybrid Log-Gamma transfer function used
+// by Rec. 2100 HDR video. c is treated as scene-linear light normalized to the 0.0-1.0 range. See
+// [Color.FromHLG] for the inverse.
+func (c Color) HLG() Color {
+	var encode = func(v float32) float32 {
+		var e = math.Max(0, float64(v))
+		if e <= 1.0/12.0 {
+			return float32(math.Sqrt(3 * e))
+		}
+		return float32(hlgA*math.Log(12*e-hlgB) + hlgC)
+	}
+	return Color{encode(c[r]), encode(c[g]), encode(c[b]), c[a]}
+}
+
+// FromHLG returns c, whose r, g and b components are HLG-encoded (see [Color.HLG]), decoded back
+// to scene-linear light normalized to the 0.0-1.0 range.
+func (c Color) FromHLG() Color {
+	var decode = func(v float32) float32 {
+		var ep = math.Max(0, float64(v))
+		if ep <= 0.5 {
+			return float32(ep * ep / 3)
+		}
+		return float32((math.Exp((float64(ep)-hlgC)/hlgA) + hlgB) / 12)
+	}
+	return Color{decode(c[r]), decode(c[g]), decode(c[b]), c[a]}
+}
+
+// ScRGB returns c's r, g and b components encoded as the signed 16-bit scRGB format used by
+// Windows' 48bpp scRGB bitmaps: linear light values in [-0.5, 7.5] are mapped onto the full int16
+// range, clamping values outside it. scRGB has no fixed alpha encoding; store alpha separately.
+func (c Color) ScRGB() [3]int16 {
+	var encode = func(v float32) int16 {
+		var x = (float64(v) + 0.5) / 8.0
+		if x < 0 {
+			x = 0
+		} else if x > 1 {
+			x = 1
+		}
+		return int16(math.Round(x*65535) - 32768)
+	}
+	return [3]int16{encode(c[r]), encode(c[g]), encode(c[b])}
+}
+
+// FromScRGB decodes a signed 16-bit scRGB triple (see [Color.ScRGB]) back to linear light values
+// in [-0.5, 7.5], with alpha 1.
+func FromScRGB(v [3]int16) Color {
+	var decode = func(iv int16) float64 {
+		var x = (float64(iv) + 32768) / 65535
+		return x*8.0 - 0.5
+	}
+	return NewColor(decode(v[0]), decode(v[1]), decode(v[2]), 1)
+}
+
+var (
+	// xyzToP3 (deltae.go) and p3ToXYZ (parse.go) are the Display-P3 primary matrices.
+	// rec2020ToXYZ and xyzToRec2020 (below/deltae.go) are the Rec. 2020 primary matrices.
+	rec2020ToXYZ = [3][3]float64{
+		{0.6369580, 0.1446169, 0.1688810},
+		{0.2627002, 0.6779981, 0.0593017},
+		{0.0000000, 0.0280727, 1.0609851},
+	}
+)
+
+// DisplayP3Linear returns x converted to linear light in the Display-P3 primaries.
+func (x XYZ) DisplayP3Linear() (r, g, b float64) { return matmul3(xyzToP3, x.X, x.Y, x.Z) }
+
+// DisplayP3LinearToXYZ converts linear light in the Display-P3 primaries to XYZ (D65).
+func DisplayP3LinearToXYZ(r, g, b float64) XYZ {
+	x, y, z := matmul3(p3ToXYZ, r, g, b)
+	return XYZ{x, y, z}
+}
+
+// Rec2020Linear returns x converted to linear light in the Rec. 2020 primaries.
+func (x XYZ) Rec2020Linear() (r, g, b float64) { return matmul3(xyzToRec2020, x.X, x.Y, x.Z) }
+
+// Rec2020LinearToXYZ converts linear light in the Rec. 2020 primaries to XYZ (D65).
+func Rec2020LinearToXYZ(r, g, b float64) XYZ {
+	x, y, z := matmul3(rec2020ToXYZ, r, g, b)
+	return XYZ{x, y, z}
+}
@@ -0,0 +1,32 @@
+package uc
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRGBE9995RoundTrip checks that EncodeRGBE9995 and RGBE9995 round-trip within the precision
+// of the shared-exponent format's 9-bit mantissas, so the rounding/overflow bump in
+// EncodeRGBE9995 can't silently regress.
+func TestRGBE9995RoundTrip(t *testing.T) {
+	var cases = []Color{
+		NewColor(1, 1, 1, 1),
+		NewColor(0.5, 0.25, 0.75, 1),
+		NewColor(2.5, 0.001, 10, 1),
+		NewColor(0, 0, 0, 1),
+		// Just below a power-of-two exponent boundary, so the max channel's mantissa rounds up to
+		// 512 and exercises the overflow bump (denom *= 2; expShared++) in EncodeRGBE9995.
+		NewColor(1.999, 1.999, 1.999, 1),
+	}
+	for _, c := range cases {
+		var decoded = RGBE9995(EncodeRGBE9995(c))
+		for i := 0; i < 3; i++ {
+			var want = float64(c[i])
+			var got = float64(decoded[i])
+			var tolerance = math.Max(want, 1) / 256
+			if math.Abs(got-want) > tolerance {
+				t.Errorf("RGBE9995(EncodeRGBE9995(%v))[%d] = %v, want ~%v", c, i, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package uc
+
+import "image/color"
+
+// RGBA implements the standard library's [color.Color] interface, returning the color's components
+// as alpha-premultiplied 16-bit values. This lets a [Color] be passed directly wherever the standard
+// library accepts a [color.Color], such as [image.NewUniform] (whose result can be the source of
+// [draw.Draw]) or [image.RGBA.Set]. Unlike [Color.RGBA32]/[Color.RGBA64],
+// which return straight (non-premultiplied) alpha, this method follows the convention required by
+// the standard library.
+func (c Color) RGBA() (rr, gg, bb, aa uint32) {
+	var ca = clampUnit(float64(c[a]))
+	aa = uint32(ca*0xffff + 0.5)
+	rr = uint32(clampUnit(float64(c[r]))*ca*0xffff + 0.5)
+	gg = uint32(clampUnit(float64(c[g]))*ca*0xffff + 0.5)
+	bb = uint32(clampUnit(float64(c[b]))*ca*0xffff + 0.5)
+	return
+}
+
+func clampUnit(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
+
+// Model is a [color.Model] that converts any [color.Color] to a [Color] via [FromStdColor].
+var Model = color.ModelFunc(func(c color.Color) color.Color {
+	return FromStdColor(c)
+})
+
+// FromStdColor converts any standard library [color.Color] into a [Color]. The source color's
+// alpha-premultiplied components are unpremultiplied and scaled to the 0.0-1.0 range.
+func FromStdColor(c color.Color) Color {
+	rr, gg, bb, aa := c.RGBA()
+	if aa == 0 {
+		return Color{}
+	}
+	return NewColor(
+		float64(rr)/float64(aa),
+		float64(gg)/float64(aa),
+		float64(bb)/float64(aa),
+		float64(aa)/0xffff,
+	)
+}
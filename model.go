@@ -137,6 +137,10 @@ func Hex64(hex int64) Color { // Color.from_hex64
 // channel value. If rgba does not contain an alpha channel value, an alpha channel value of 1.0 is
 // applied. If rgba is invalid, returns an empty color.
 //
+// See also [Parse], which accepts hex strings as well as the rest of the CSS Color Module Level 4
+// grammar (named colors, rgb(), hsl(), lab(), oklch(), ...) and reports errors instead of failing
+// silently.
+//
 //	var blue = HTML("#0000ff") // blue is Color{0.0, 0.0, 1.0, 1.0}
 //	var green = HTML("#0F0")   // green is Color{0.0, 1.0, 0.0, 1.0}
 //	var col = HTML("663399cc") // col is Color{0.4, 0.2, 0.6, 0.8}
@@ -187,9 +191,9 @@ func _parse_col4(s string, ofs int) float64 {
 	if character >= '0' && character <= '9' {
 		return float64(character - '0')
 	} else if character >= 'a' && character <= 'f' {
-		return float64(character) + float64(10-'a')
+		return float64(character-'a') + 10
 	} else if character >= 'A' && character <= 'F' {
-		return float64(character) + float64(10-'A')
+		return float64(character-'A') + 10
 	}
 	return -1
 }
@@ -0,0 +1,453 @@
+package uc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grow-graphics/uc/named"
+)
+
+// ErrBadLength is returned by [Parse] when the string is empty, when a hex color string is not 3,
+// 4, 6 or 8 digits long, or when a color function has the wrong number of arguments. Length is
+// the digit or argument count that was found.
+type ErrBadLength struct{ Length int }
+
+func (e ErrBadLength) Error() string {
+	return fmt.Sprintf("uc: invalid color length %d", e.Length)
+}
+
+// ErrBadHexDigit is returned by [Parse] when a hex color string contains a non-hexadecimal byte.
+type ErrBadHexDigit struct {
+	Index int
+	Byte  byte
+}
+
+func (e ErrBadHexDigit) Error() string {
+	return fmt.Sprintf("uc: invalid hex digit %q at index %d", e.Byte, e.Index)
+}
+
+// ErrUnknownFunction is returned by [Parse] when a CSS function name is not recognised.
+type ErrUnknownFunction struct{ Name string }
+
+func (e ErrUnknownFunction) Error() string {
+	return fmt.Sprintf("uc: unknown color function %q", e.Name)
+}
+
+// ErrUnknownName is returned by [Parse] when a color keyword is not recognised.
+type ErrUnknownName struct{ Name string }
+
+func (e ErrUnknownName) Error() string {
+	return fmt.Sprintf("uc: unknown color name %q", e.Name)
+}
+
+// Parse parses s as any color string accepted by the CSS Color Module Level 4 grammar: hex colors
+// (#rgb, #rgba, #rrggbb, #rrggbbaa), rgb()/rgba() (legacy comma and modern slash-alpha syntax),
+// hsl()/hsla(), hwb(), lab()/lch(), oklab()/oklch(), color(display-p3 ...), the ~150 CSS named
+// colors (see package [named]), and the "transparent" keyword. Unlike [HTML], which has no error
+// result and so cannot report a malformed string, Parse returns a typed error ([ErrBadLength],
+// [ErrBadHexDigit], [ErrUnknownFunction], [ErrUnknownName]) describing what went wrong.
+func Parse(s string) (Color, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Color{}, ErrBadLength{0}
+	}
+	if s[0] == '#' {
+		return parseHex(s[1:])
+	}
+	if open := strings.IndexByte(s, '('); open != -1 && strings.HasSuffix(s, ")") {
+		name := strings.ToLower(strings.TrimSpace(s[:open]))
+		args := splitArgs(s[open+1 : len(s)-1])
+		return parseFunction(name, args)
+	}
+	switch strings.ToLower(s) {
+	case "transparent":
+		return Color{}, nil
+	}
+	if rgb, ok := named.Colors[strings.ToLower(s)]; ok {
+		return RGBA(rgb[0], rgb[1], rgb[2]), nil
+	}
+	return Color{}, ErrUnknownName{s}
+}
+
+func parseHex(digits string) (Color, error) {
+	switch len(digits) {
+	case 3, 4, 6, 8:
+	default:
+		return Color{}, ErrBadLength{len(digits)}
+	}
+	nibble := func(i int) (float64, error) {
+		c := digits[i]
+		switch {
+		case c >= '0' && c <= '9':
+			return float64(c - '0'), nil
+		case c >= 'a' && c <= 'f':
+			return float64(c-'a') + 10, nil
+		case c >= 'A' && c <= 'F':
+			return float64(c-'A') + 10, nil
+		default:
+			return 0, ErrBadHexDigit{i, c}
+		}
+	}
+	if len(digits) <= 4 {
+		var v [4]float64
+		var err error
+		for i := range digits {
+			if v[i], err = nibble(i); err != nil {
+				return Color{}, err
+			}
+			v[i] /= 15
+		}
+		var a = 1.0
+		if len(digits) == 4 {
+			a = v[3]
+		}
+		return NewColor(v[0], v[1], v[2], a), nil
+	}
+	byteAt := func(i int) (float64, error) {
+		hi, err := nibble(i)
+		if err != nil {
+			return 0, err
+		}
+		lo, err := nibble(i + 1)
+		if err != nil {
+			return 0, err
+		}
+		return (hi*16 + lo) / 255, nil
+	}
+	r, err := byteAt(0)
+	if err != nil {
+		return Color{}, err
+	}
+	g, err := byteAt(2)
+	if err != nil {
+		return Color{}, err
+	}
+	b, err := byteAt(4)
+	if err != nil {
+		return Color{}, err
+	}
+	var alpha = 1.0
+	if len(digits) == 8 {
+		if alpha, err = byteAt(6); err != nil {
+			return Color{}, err
+		}
+	}
+	return NewColor(r, g, b, alpha), nil
+}
+
+// splitArgs splits a CSS function's argument list, accepting both the legacy comma-separated
+// syntax ("255, 0, 0, 0.5") and the modern whitespace/slash syntax ("255 0 0 / 50%").
+func splitArgs(s string) []string {
+	s = strings.ReplaceAll(s, ",", " ")
+	s = strings.ReplaceAll(s, "/", " / ")
+	return strings.Fields(s)
+}
+
+// p3ToXYZ is the linear Display-P3 -> XYZ (D65) matrix, used by the color(display-p3 ...) syntax.
+var p3ToXYZ = [3][3]float64{
+	{0.4865709, 0.2656677, 0.1982173},
+	{0.2289746, 0.6917385, 0.0792869},
+	{0.0000000, 0.0451134, 1.0439444},
+}
+
+func parseFunction(name string, args []string) (Color, error) {
+	// color(display-p3 r g b) always has 4 bare arguments, so it can't use the legacy
+	// comma-syntax heuristic that treats a bare 4th argument as alpha; only a "/" counts there.
+	var alpha = 1.0
+	var ok bool
+	if alpha, args, ok = extractSlashAlpha(args); !ok && name != "color" {
+		alpha, args = extractAlpha(args)
+	}
+	switch name {
+	case "rgb", "rgba":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		r, err := parseChannel(args[0])
+		if err != nil {
+			return Color{}, err
+		}
+		g, err := parseChannel(args[1])
+		if err != nil {
+			return Color{}, err
+		}
+		b, err := parseChannel(args[2])
+		if err != nil {
+			return Color{}, err
+		}
+		return NewColor(r, g, b, alpha), nil
+	case "hsl", "hsla":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		h, err := parseHue(args[0])
+		if err != nil {
+			return Color{}, err
+		}
+		s, err := parseUnit(args[1])
+		if err != nil {
+			return Color{}, err
+		}
+		l, err := parseUnit(args[2])
+		if err != nil {
+			return Color{}, err
+		}
+		c := HSL{H: h, S: s, L: l}.Color()
+		c[a] = float32(alpha)
+		return c, nil
+	case "hwb":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		h, err := parseHue(args[0])
+		if err != nil {
+			return Color{}, err
+		}
+		w, err := parseUnit(args[1])
+		if err != nil {
+			return Color{}, err
+		}
+		bl, err := parseUnit(args[2])
+		if err != nil {
+			return Color{}, err
+		}
+		c := hwbToColor(h, w, bl)
+		c[a] = float32(alpha)
+		return c, nil
+	case "lab":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		l, err := parseScaled(args[0], 100, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		aVal, err := parseScaled(args[1], 125, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		bVal, err := parseScaled(args[2], 125, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		c := Lab{L: l, A: aVal, B: bVal}.XYZ().Color()
+		c[a] = float32(alpha)
+		return c, nil
+	case "lch":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		l, err := parseScaled(args[0], 100, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		cc, err := parseScaled(args[1], 150, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		h, err := parseHue(args[2])
+		if err != nil {
+			return Color{}, err
+		}
+		c := LCH{L: l, C: cc, H: h}.Lab().XYZ().Color()
+		c[a] = float32(alpha)
+		return c, nil
+	case "oklab":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		l, err := parseScaled(args[0], 1, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		aVal, err := parseScaled(args[1], 0.4, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		bVal, err := parseScaled(args[2], 0.4, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		c := Oklab{L: l, A: aVal, B: bVal}.Color()
+		c[a] = float32(alpha)
+		return c, nil
+	case "oklch":
+		if len(args) != 3 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		l, err := parseScaled(args[0], 1, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		cc, err := parseScaled(args[1], 0.4, 1)
+		if err != nil {
+			return Color{}, err
+		}
+		h, err := parseHue(args[2])
+		if err != nil {
+			return Color{}, err
+		}
+		c := Oklch{L: l, C: cc, H: h}.Color()
+		c[a] = float32(alpha)
+		return c, nil
+	case "color":
+		if len(args) != 4 {
+			return Color{}, ErrBadLength{len(args)}
+		}
+		switch strings.ToLower(args[0]) {
+		case "display-p3":
+			pr, err := parseUnit(args[1])
+			if err != nil {
+				return Color{}, err
+			}
+			pg, err := parseUnit(args[2])
+			if err != nil {
+				return Color{}, err
+			}
+			pb, err := parseUnit(args[3])
+			if err != nil {
+				return Color{}, err
+			}
+			var lin = NewColor(pr, pg, pb, 1).Linear()
+			x, y, z := matmul3(p3ToXYZ, float64(lin[r]), float64(lin[g]), float64(lin[b]))
+			c := XYZ{x, y, z}.Color()
+			c[a] = float32(alpha)
+			return c, nil
+		default:
+			return Color{}, ErrUnknownFunction{"color(" + args[0] + ")"}
+		}
+	default:
+		return Color{}, ErrUnknownFunction{name}
+	}
+}
+
+// extractSlashAlpha pulls a modern "/ alpha" argument off args, reporting whether one was found.
+// alpha defaults to 1.0 and args is returned unmodified when it wasn't.
+func extractSlashAlpha(args []string) (alpha float64, rest []string, ok bool) {
+	for i, tok := range args {
+		if tok == "/" {
+			if i+1 < len(args) {
+				if v, err := parseUnit(args[i+1]); err == nil {
+					return v, args[:i], true
+				}
+			}
+			return 1, args[:i], true
+		}
+	}
+	return 1, args, false
+}
+
+// extractAlpha pulls the alpha component off args, returning the alpha (defaulting to 1.0) and
+// the remaining color-component arguments. It accepts both the modern "/ alpha" syntax (via
+// [extractSlashAlpha]) and the legacy syntax where alpha is simply a fourth comma-separated
+// argument (e.g. "rgba(0,255,0,0.5)").
+func extractAlpha(args []string) (float64, []string) {
+	if alpha, rest, ok := extractSlashAlpha(args); ok {
+		return alpha, rest
+	}
+	if len(args) == 4 {
+		if v, err := parseUnit(args[3]); err == nil {
+			return v, args[:3]
+		}
+	}
+	return 1, args
+}
+
+func parseUnit(tok string) (float64, error) {
+	if strings.HasSuffix(tok, "%") {
+		v, err := strconv.ParseFloat(strings.TrimSuffix(tok, "%"), 64)
+		return v / 100, err
+	}
+	return strconv.ParseFloat(tok, 64)
+}
+
+// parseChannel parses an rgb() channel: a percentage of 0%-100%, or a bare number of 0-255.
+func parseChannel(tok string) (float64, error) {
+	if strings.HasSuffix(tok, "%") {
+		return parseUnit(tok)
+	}
+	v, err := strconv.ParseFloat(tok, 64)
+	return v / 255, err
+}
+
+// parseScaled parses a Lab/LCH/Oklab/Oklch component: a percentage of the CSS reference range, or
+// a bare number already in absoluteScale units.
+func parseScaled(tok string, percentBasis, absoluteScale float64) (float64, error) {
+	if strings.HasSuffix(tok, "%") {
+		v, err := parseUnit(tok)
+		return v * percentBasis, err
+	}
+	v, err := strconv.ParseFloat(tok, 64)
+	return v * absoluteScale, err
+}
+
+// parseHue parses an angle as a fraction of a full turn (0.0-1.0), defaulting to degrees.
+func parseHue(tok string) (float64, error) {
+	switch {
+	case strings.HasSuffix(tok, "deg"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(tok, "deg"), 64)
+		return v / 360, err
+	case strings.HasSuffix(tok, "grad"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(tok, "grad"), 64)
+		return v / 400, err
+	case strings.HasSuffix(tok, "rad"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(tok, "rad"), 64)
+		return v / (2 * 3.141592653589793), err
+	case strings.HasSuffix(tok, "turn"):
+		return strconv.ParseFloat(strings.TrimSuffix(tok, "turn"), 64)
+	default:
+		v, err := strconv.ParseFloat(tok, 64)
+		return v / 360, err
+	}
+}
+
+func hwbToColor(h, w, bl float64) Color {
+	if w+bl >= 1 {
+		var gray = w / (w + bl)
+		return NewColor(gray, gray, gray, 1)
+	}
+	var c = HSL{H: h, S: 1, L: 0.5}.Color()
+	for i := r; i <= b; i++ {
+		c[i] = float32(float64(c[i])*(1-w-bl) + w)
+	}
+	return c
+}
+
+// Format renders c as a CSS Color Module Level 4 string using the notation named by spec: "hex",
+// "rgb", "hsl", "lab", "lch", "oklab" or "oklch". It is the counterpart to [Parse], so that
+// c.Format(spec) round-trips through [Parse] (modulo floating-point and gamut-clamping error).
+func (c Color) Format(spec string) string {
+	var hex = func() string {
+		to8 := func(v float32) int { return int(clampUnit(float64(v))*255 + 0.5) }
+		return fmt.Sprintf("#%02x%02x%02x%02x", to8(c[r]), to8(c[g]), to8(c[b]), to8(c[a]))
+	}
+	switch strings.ToLower(spec) {
+	case "hex":
+		return hex()
+	case "rgb":
+		return fmt.Sprintf("rgb(%d %d %d / %s)", int(clampUnit(float64(c[r]))*255+0.5),
+			int(clampUnit(float64(c[g]))*255+0.5), int(clampUnit(float64(c[b]))*255+0.5), formatUnit(float64(c[a])))
+	case "hsl":
+		h := c.HSL()
+		return fmt.Sprintf("hsl(%sdeg %s%% %s%% / %s)", formatNumber(h.H*360),
+			formatNumber(h.S*100), formatNumber(h.L*100), formatUnit(float64(c[a])))
+	case "lab":
+		l := c.XYZ().Lab()
+		return fmt.Sprintf("lab(%s%% %s %s / %s)", formatNumber(l.L), formatNumber(l.A), formatNumber(l.B), formatUnit(float64(c[a])))
+	case "lch":
+		l := c.XYZ().Lab().LCH()
+		return fmt.Sprintf("lch(%s%% %s %sdeg / %s)", formatNumber(l.L), formatNumber(l.C), formatNumber(l.H*360), formatUnit(float64(c[a])))
+	case "oklab":
+		o := c.Oklab()
+		return fmt.Sprintf("oklab(%s %s %s / %s)", formatUnit(o.L), formatNumber(o.A), formatNumber(o.B), formatUnit(float64(c[a])))
+	case "oklch":
+		o := c.Oklch()
+		return fmt.Sprintf("oklch(%s %s %sdeg / %s)", formatUnit(o.L), formatNumber(o.C), formatNumber(o.H*360), formatUnit(float64(c[a])))
+	default:
+		return hex()
+	}
+}
+
+func formatNumber(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+func formatUnit(v float64) string   { return strconv.FormatFloat(v, 'g', -1, 64) }